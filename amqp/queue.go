@@ -0,0 +1,321 @@
+package amqp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+
+	"github.com/go-msgqueue/msgqueue"
+	"github.com/go-msgqueue/msgqueue/internal"
+	"github.com/go-msgqueue/msgqueue/memqueue"
+	"github.com/go-msgqueue/msgqueue/processor"
+	"github.com/go-msgqueue/msgqueue/service"
+)
+
+// maxReserveN caps how many unacked deliveries the consumer prefetches
+// and ReserveN returns at once.
+const maxReserveN = 100
+
+type Queue struct {
+	ch  *amqp.Channel
+	opt *msgqueue.Options
+
+	memqueue *memqueue.Queue
+	p        *processor.Processor
+
+	declareOnce sync.Once
+	declareErr  error
+
+	consumeOnce sync.Once
+	consumeErr  error
+	deliveries  <-chan amqp.Delivery
+
+	service.BaseService
+}
+
+var _ processor.Queuer = (*Queue)(nil)
+var _ service.Service = (*Queue)(nil)
+
+func NewQueue(ch *amqp.Channel, opt *msgqueue.Options) *Queue {
+	opt.Init()
+
+	q := Queue{
+		ch:  ch,
+		opt: opt,
+	}
+
+	memopt := msgqueue.Options{
+		Name:      opt.Name,
+		GroupName: opt.GroupName,
+
+		RetryLimit: 3,
+		MinBackoff: time.Second,
+		Handler:    msgqueue.HandlerFunc(q.add),
+
+		Redis: opt.Redis,
+	}
+	if opt.Handler != nil {
+		memopt.FallbackHandler = internal.MessageUnwrapperHandler(opt.Handler)
+	}
+	q.memqueue = memqueue.NewQueue(&memopt)
+
+	return &q
+}
+
+func (q *Queue) Name() string {
+	return q.opt.Name
+}
+
+func (q *Queue) String() string {
+	return fmt.Sprintf("Queue<Name=%s>", q.Name())
+}
+
+func (q *Queue) Options() *msgqueue.Options {
+	return q.opt
+}
+
+func (q *Queue) Processor() *processor.Processor {
+	if q.p == nil {
+		q.p = processor.New(q, q.opt)
+	}
+	return q.p
+}
+
+// declareQueue declares the queue, its dead-letter exchange/queue used for
+// the per-message TTL + DLX delay pattern, and binds them together. It
+// only runs once per Queue instance.
+func (q *Queue) declareQueue() error {
+	q.declareOnce.Do(func() {
+		name := q.Name()
+		delayName := name + ".delay"
+
+		q.declareErr = q.ch.ExchangeDeclare(name, "direct", true, false, false, false, nil)
+		if q.declareErr != nil {
+			return
+		}
+		if _, q.declareErr = q.ch.QueueDeclare(name, true, false, false, false, nil); q.declareErr != nil {
+			return
+		}
+		if q.declareErr = q.ch.QueueBind(name, name, name, false, nil); q.declareErr != nil {
+			return
+		}
+
+		// Delay queue has no consumers; messages expire into the real
+		// queue via the DLX once their per-message TTL elapses.
+		q.declareErr = q.ch.ExchangeDeclare(delayName, "direct", true, false, false, false, nil)
+		if q.declareErr != nil {
+			return
+		}
+		_, q.declareErr = q.ch.QueueDeclare(delayName, true, false, false, false, amqp.Table{
+			"x-dead-letter-exchange":    name,
+			"x-dead-letter-routing-key": name,
+		})
+		if q.declareErr != nil {
+			return
+		}
+		q.declareErr = q.ch.QueueBind(delayName, delayName, delayName, false, nil)
+	})
+	return q.declareErr
+}
+
+func (q *Queue) add(msg *msgqueue.Message) error {
+	msg = msg.Args[0].(*msgqueue.Message)
+
+	if err := q.declareQueue(); err != nil {
+		return err
+	}
+
+	if msg.Id == "" {
+		msg.Id = newMessageId()
+	}
+
+	body, err := q.opt.Codec.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	publishing := amqp.Publishing{
+		Body:         body,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.Id,
+	}
+
+	exchange := q.Name()
+	routingKey := q.Name()
+	if msg.Delay > 0 {
+		exchange = q.Name() + ".delay"
+		routingKey = exchange
+		publishing.Expiration = fmt.Sprintf("%d", int64(msg.Delay/time.Millisecond))
+	}
+
+	return q.ch.Publish(exchange, routingKey, false, false, publishing)
+}
+
+// Add adds message to the queue.
+func (q *Queue) Add(msg *msgqueue.Message) error {
+	return q.memqueue.Add(internal.WrapMessage(msg))
+}
+
+// Call creates a message using the args and adds it to the queue.
+func (q *Queue) Call(args ...interface{}) error {
+	msg := msgqueue.NewMessage(args...)
+	return q.Add(msg)
+}
+
+// CallOnce works like Call, but it adds message with same args
+// only once in a period.
+func (q *Queue) CallOnce(period time.Duration, args ...interface{}) error {
+	msg := msgqueue.NewMessage(args...)
+	msg.SetDelayName(period, args...)
+	return q.Add(msg)
+}
+
+// consume declares a single long-lived consumer for the queue and caches
+// its delivery channel on Queue. Calling ReserveN repeatedly reuses that
+// channel instead of issuing a fresh basic.Consume (and abandoning the
+// previous one's deliveries) on every call.
+func (q *Queue) consume() error {
+	q.consumeOnce.Do(func() {
+		if q.consumeErr = q.ch.Qos(maxReserveN, 0, false); q.consumeErr != nil {
+			return
+		}
+		q.deliveries, q.consumeErr = q.ch.Consume(q.Name(), "", false, false, false, false, nil)
+	})
+	return q.consumeErr
+}
+
+func (q *Queue) ReserveN(n int) ([]msgqueue.Message, error) {
+	if err := q.declareQueue(); err != nil {
+		return nil, err
+	}
+	if n > maxReserveN {
+		n = maxReserveN
+	}
+
+	if err := q.consume(); err != nil {
+		return nil, err
+	}
+
+	var msgs []msgqueue.Message
+	for i := 0; i < n; i++ {
+		select {
+		case d, ok := <-q.deliveries:
+			if !ok {
+				return msgs, nil
+			}
+			var msg msgqueue.Message
+			if err := q.opt.Codec.Unmarshal(d.Body, &msg); err != nil {
+				return msgs, err
+			}
+			msg.Id = d.MessageId
+			msg.Body = string(d.Body)
+			msg.DeliveryTag = d.DeliveryTag
+			msg.ReservedCount = 1
+			msgs = append(msgs, msg)
+		case <-time.After(q.opt.ReservationTimeout):
+			return msgs, nil
+		}
+	}
+	return msgs, nil
+}
+
+func (q *Queue) Release(msg *msgqueue.Message, delay time.Duration) error {
+	if delay > 0 {
+		// Republish with a delay rather than requeue immediately, since
+		// AMQP has no native per-message delay on Nack.
+		if err := q.ch.Ack(msg.DeliveryTag, false); err != nil {
+			return err
+		}
+		msg.Delay = delay
+		return q.add(&msgqueue.Message{Args: []interface{}{msg}})
+	}
+	return q.ch.Nack(msg.DeliveryTag, false, true)
+}
+
+func (q *Queue) Delete(msg *msgqueue.Message) error {
+	return q.ch.Ack(msg.DeliveryTag, false)
+}
+
+func (q *Queue) DeleteBatch(msgs []*msgqueue.Message) error {
+	for _, msg := range msgs {
+		if err := q.Delete(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *Queue) Purge() error {
+	_, err := q.ch.QueuePurge(q.Name(), false)
+	return err
+}
+
+// newMessageId generates the id assigned to a message when it's added,
+// since unlike ironmq's PushMessage, AMQP's basic.Publish doesn't hand
+// one back.
+func newMessageId() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Start marks the queue as running. The underlying memqueue.Queue and
+// processor.Processor don't implement service.Service yet (that
+// conversion is a follow-up against those packages), so there is no
+// inner Start to call here — they begin processing as soon as they're
+// constructed, same as before.
+func (q *Queue) Start(ctx context.Context) error {
+	return q.BaseService.Starting()
+}
+
+// Stop stops the queue, waiting for pending messages to be processed
+// until ctx's deadline, or for up to 30 seconds if ctx has none.
+// Cancelling ctx does not end the wait early. Calling Stop before
+// Start, or more than once, is a no-op.
+func (q *Queue) Stop(ctx context.Context) error {
+	if !q.BaseService.Stopping() {
+		return nil
+	}
+
+	timeout := stopTimeout(ctx)
+
+	var firstErr error
+	if err := q.memqueue.CloseTimeout(timeout); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if q.p != nil {
+		if err := q.p.StopTimeout(timeout); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	q.BaseService.Stopped(firstErr)
+	return firstErr
+}
+
+// stopTimeout derives the timeout CloseTimeout/StopTimeout need from
+// ctx's deadline, falling back to 30 seconds if ctx has none.
+func stopTimeout(ctx context.Context) time.Duration {
+	if dl, ok := ctx.Deadline(); ok {
+		return time.Until(dl)
+	}
+	return 30 * time.Second
+}
+
+// Close is CloseTimeout with 30 seconds timeout.
+func (q *Queue) Close() error {
+	return q.CloseTimeout(30 * time.Second)
+}
+
+// CloseTimeout is a thin wrapper over Stop with a timeout, kept for
+// callers that don't manage a context.
+func (q *Queue) CloseTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.Stop(ctx)
+}
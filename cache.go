@@ -0,0 +1,15 @@
+package msgqueue
+
+import "time"
+
+// Cache is used to deduplicate named messages and to back CallOnce.
+type Cache interface {
+	// Exists reports whether key is already present, setting it if not.
+	Exists(key string) bool
+
+	// SetNX sets key with the given ttl and reports whether it was not
+	// already present. It lets callers combine the check-and-set that
+	// Exists performs implicitly with their own ttl, and lets layered
+	// caches short-circuit a lower layer without a round trip.
+	SetNX(key string, ttl time.Duration) bool
+}
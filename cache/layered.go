@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/go-msgqueue/msgqueue"
+)
+
+// Layered wraps two Caches: an in-process LRU as L1 and any
+// msgqueue.Cache, typically backed by Redis, as L2. Exists checks L1
+// first and only falls back to L2 on a miss, hoisting L2 hits into L1
+// so repeated lookups for the same key stay local.
+type Layered struct {
+	L1 *LRU
+	L2 msgqueue.Cache
+}
+
+var _ msgqueue.Cache = (*Layered)(nil)
+
+// NewLayered creates a Layered cache with an L1 LRU holding at most
+// l1Size entries in front of l2.
+func NewLayered(l1Size int, l2 msgqueue.Cache) *Layered {
+	return &Layered{
+		L1: NewLRU(l1Size),
+		L2: l2,
+	}
+}
+
+func (c *Layered) Exists(key string) bool {
+	if c.L1.peek(key) {
+		return true
+	}
+	if !c.L2.Exists(key) {
+		return false
+	}
+	c.L1.SetNX(key, defaultTTL)
+	return true
+}
+
+func (c *Layered) SetNX(key string, ttl time.Duration) bool {
+	if !c.L1.SetNX(key, ttl) {
+		return false
+	}
+	return c.L2.SetNX(key, ttl)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeCache is a minimal msgqueue.Cache used to observe how Layered calls
+// into L2 without needing Redis.
+type fakeCache struct {
+	exists      map[string]bool
+	existsCalls int
+	setNXCalls  int
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{exists: make(map[string]bool)}
+}
+
+func (c *fakeCache) Exists(key string) bool {
+	c.existsCalls++
+	return c.exists[key]
+}
+
+func (c *fakeCache) SetNX(key string, ttl time.Duration) bool {
+	c.setNXCalls++
+	if c.exists[key] {
+		return false
+	}
+	c.exists[key] = true
+	return true
+}
+
+func TestLayeredL1HitShortCircuitsL2(t *testing.T) {
+	l2 := newFakeCache()
+	c := NewLayered(10, l2)
+
+	c.L1.SetNX("a", time.Hour)
+
+	if !c.Exists("a") {
+		t.Fatal("Exists should report true for a key present in L1")
+	}
+	if l2.existsCalls != 0 {
+		t.Fatalf("L2.Exists should not be called when L1 already has the key, got %d calls", l2.existsCalls)
+	}
+}
+
+func TestLayeredL2HitHoistsIntoL1(t *testing.T) {
+	l2 := newFakeCache()
+	l2.exists["a"] = true
+	c := NewLayered(10, l2)
+
+	if !c.Exists("a") {
+		t.Fatal("Exists should report true for a key present in L2")
+	}
+	if !c.L1.peek("a") {
+		t.Fatal("a hit in L2 should be hoisted into L1")
+	}
+
+	l2.existsCalls = 0
+	if !c.Exists("a") {
+		t.Fatal("Exists should still report true once hoisted into L1")
+	}
+	if l2.existsCalls != 0 {
+		t.Fatalf("L2.Exists should not be called once L1 holds the hoisted key, got %d calls", l2.existsCalls)
+	}
+}
+
+func TestLayeredExistsMissesBothLayers(t *testing.T) {
+	l2 := newFakeCache()
+	c := NewLayered(10, l2)
+
+	if c.Exists("missing") {
+		t.Fatal("Exists should report false when neither layer has the key")
+	}
+}
+
+func TestLayeredSetNX(t *testing.T) {
+	l2 := newFakeCache()
+	c := NewLayered(10, l2)
+
+	if !c.SetNX("a", time.Hour) {
+		t.Fatal("SetNX should report true for a new key")
+	}
+	if !c.L1.peek("a") {
+		t.Fatal("SetNX should populate L1")
+	}
+	if !l2.exists["a"] {
+		t.Fatal("SetNX should populate L2")
+	}
+	if c.SetNX("a", time.Hour) {
+		t.Fatal("SetNX should report false once L1 already holds the key")
+	}
+}
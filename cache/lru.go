@@ -0,0 +1,101 @@
+// Package cache provides msgqueue.Cache implementations beyond the
+// single Redis-backed cache used in tests: an in-process LRU and a
+// Layered cache that puts one in front of an arbitrary L2.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/go-msgqueue/msgqueue"
+)
+
+const defaultTTL = 12 * time.Hour
+
+type lruEntry struct {
+	key      string
+	expireAt time.Time
+}
+
+// LRU is a fixed-size, in-process cache with a per-entry TTL. It
+// implements msgqueue.Cache so it can be used standalone as
+// Options.Cache, or as the L1 of a Layered cache.
+type LRU struct {
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+var _ msgqueue.Cache = (*LRU)(nil)
+
+// NewLRU creates an LRU that holds at most size entries.
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *LRU) Exists(key string) bool {
+	return !c.SetNX(key, defaultTTL)
+}
+
+// SetNX sets key with ttl and reports whether it was not already
+// present. An expired entry is treated as absent.
+func (c *LRU) SetNX(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		if time.Now().Before(entry.expireAt) {
+			c.ll.MoveToFront(el)
+			return false
+		}
+		c.removeElement(el)
+	}
+
+	el := c.ll.PushFront(&lruEntry{key: key, expireAt: time.Now().Add(ttl)})
+	c.items[key] = el
+	c.evict()
+	return true
+}
+
+// peek reports whether key is present without inserting it when it is
+// not, unlike Exists/SetNX.
+func (c *LRU) peek(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expireAt) {
+		c.removeElement(el)
+		return false
+	}
+	c.ll.MoveToFront(el)
+	return true
+}
+
+func (c *LRU) evict() {
+	for c.ll.Len() > c.size {
+		el := c.ll.Back()
+		if el == nil {
+			return
+		}
+		c.removeElement(el)
+	}
+}
+
+func (c *LRU) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	entry := el.Value.(*lruEntry)
+	delete(c.items, entry.key)
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUSetNX(t *testing.T) {
+	c := NewLRU(10)
+
+	if !c.SetNX("a", time.Hour) {
+		t.Fatal("SetNX should report true for a new key")
+	}
+	if c.SetNX("a", time.Hour) {
+		t.Fatal("SetNX should report false for a key that's already present")
+	}
+	if !c.Exists("a") {
+		t.Fatal("Exists should be true after SetNX")
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2)
+
+	c.SetNX("a", time.Hour)
+	c.SetNX("b", time.Hour)
+	c.SetNX("c", time.Hour)
+
+	if c.peek("a") {
+		t.Fatal("a should have been evicted to make room for c")
+	}
+	if !c.peek("b") || !c.peek("c") {
+		t.Fatal("b and c should still be present")
+	}
+}
+
+func TestLRUPeekRefreshesRecency(t *testing.T) {
+	c := NewLRU(2)
+
+	c.SetNX("a", time.Hour)
+	c.SetNX("b", time.Hour)
+	c.peek("a") // touch a so it's no longer the least recently used
+	c.SetNX("c", time.Hour)
+
+	if c.peek("b") {
+		t.Fatal("b should have been evicted, a was touched more recently")
+	}
+	if !c.peek("a") || !c.peek("c") {
+		t.Fatal("a and c should still be present")
+	}
+}
+
+func TestLRUExpiredEntryTreatedAsAbsent(t *testing.T) {
+	c := NewLRU(10)
+
+	c.SetNX("a", -time.Second)
+
+	if c.peek("a") {
+		t.Fatal("expired entry should not be visible to peek")
+	}
+	if !c.SetNX("a", time.Hour) {
+		t.Fatal("SetNX should report true for an expired key, same as an absent one")
+	}
+}
@@ -0,0 +1,27 @@
+package msgqueue
+
+import "encoding/json"
+
+// Codec marshals and unmarshals a Message to and from the wire
+// representation a backend stores as the message body. Backends must
+// route Add/ReserveN through Options.Codec instead of encoding the
+// message themselves, so payload formats can be swapped per queue.
+type Codec interface {
+	Marshal(msg *Message) ([]byte, error)
+	Unmarshal(b []byte, msg *Message) error
+}
+
+// jsonCodec is the default Codec and preserves the historical encoding,
+// which only round-trips Message.Args.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(msg *Message) ([]byte, error) {
+	return json.Marshal(msg.Args)
+}
+
+func (jsonCodec) Unmarshal(b []byte, msg *Message) error {
+	return json.Unmarshal(b, &msg.Args)
+}
+
+// DefaultCodec is used when Options.Codec is not set.
+var DefaultCodec Codec = jsonCodec{}
@@ -0,0 +1,60 @@
+package protobuf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-msgqueue/msgqueue"
+	"github.com/go-msgqueue/msgqueue/codec/protobuf"
+)
+
+func TestRoundTrip(t *testing.T) {
+	msg := &msgqueue.Message{
+		Id:                  "1",
+		Name:                "myname",
+		Args:                []interface{}{"string", float64(42), true, nil},
+		Delay:               5 * time.Second,
+		ReservedCount:       2,
+		ReservationDeadline: time.Unix(1700000000, 0),
+	}
+
+	var codec protobuf.Codec
+	b, err := codec.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out msgqueue.Message
+	if err := codec.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Id != msg.Id || out.Name != msg.Name || out.Delay != msg.Delay || out.ReservedCount != msg.ReservedCount {
+		t.Fatalf("got %+v, want %+v", out, msg)
+	}
+	if !out.ReservationDeadline.Equal(msg.ReservationDeadline) {
+		t.Fatalf("ReservationDeadline = %v, want %v", out.ReservationDeadline, msg.ReservationDeadline)
+	}
+	if len(out.Args) != len(msg.Args) {
+		t.Fatalf("got %d args, want %d", len(out.Args), len(msg.Args))
+	}
+}
+
+func TestRoundTripNoDeadline(t *testing.T) {
+	msg := &msgqueue.Message{Id: "1"}
+
+	var codec protobuf.Codec
+	b, err := codec.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out msgqueue.Message
+	if err := codec.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !out.ReservationDeadline.IsZero() {
+		t.Fatalf("ReservationDeadline = %v, want zero value", out.ReservationDeadline)
+	}
+}
@@ -0,0 +1,13 @@
+package msgqueue
+
+// Handler processes messages reserved off a queue.
+type Handler interface {
+	HandleMessage(msg *Message) error
+}
+
+// HandlerFunc is an adapter to use ordinary functions as a Handler.
+type HandlerFunc func(msg *Message) error
+
+func (fn HandlerFunc) HandleMessage(msg *Message) error {
+	return fn(msg)
+}
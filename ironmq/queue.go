@@ -1,6 +1,7 @@
 package ironmq
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/go-msgqueue/msgqueue/internal"
 	"github.com/go-msgqueue/msgqueue/memqueue"
 	"github.com/go-msgqueue/msgqueue/processor"
+	"github.com/go-msgqueue/msgqueue/service"
 
 	"github.com/iron-io/iron_go3/api"
 	"github.com/iron-io/iron_go3/mq"
@@ -20,9 +22,12 @@ type Queue struct {
 	memqueue *memqueue.Queue
 
 	p *processor.Processor
+
+	service.BaseService
 }
 
 var _ processor.Queuer = (*Queue)(nil)
+var _ service.Service = (*Queue)(nil)
 
 func NewQueue(mqueue mq.Queue, opt *msgqueue.Options) *Queue {
 	if opt.Name == "" {
@@ -81,7 +86,7 @@ func (q *Queue) createQueue() error {
 func (q *Queue) add(msg *msgqueue.Message) error {
 	msg = msg.Args[0].(*msgqueue.Message)
 
-	body, err := msg.MarshalArgs()
+	body, err := q.opt.Codec.Marshal(msg)
 	if err != nil {
 		return err
 	}
@@ -134,15 +139,17 @@ func (q *Queue) ReserveN(n int) ([]msgqueue.Message, error) {
 		return nil, err
 	}
 
-	msgs := make([]msgqueue.Message, len(mqMsgs))
-	for i, mqMsg := range mqMsgs {
-		msgs[i] = msgqueue.Message{
-			Id:   mqMsg.Id,
-			Body: mqMsg.Body,
-
-			ReservationId: mqMsg.ReservationId,
-			ReservedCount: mqMsg.ReservedCount,
+	msgs := make([]msgqueue.Message, 0, len(mqMsgs))
+	for _, mqMsg := range mqMsgs {
+		var msg msgqueue.Message
+		if err := q.opt.Codec.Unmarshal([]byte(mqMsg.Body), &msg); err != nil {
+			return msgs, err
 		}
+		msg.Id = mqMsg.Id
+		msg.Body = mqMsg.Body
+		msg.ReservationId = mqMsg.ReservationId
+		msg.ReservedCount = mqMsg.ReservedCount
+		msgs = append(msgs, msg)
 	}
 	return msgs, nil
 }
@@ -183,13 +190,26 @@ func (q *Queue) Purge() error {
 	return q.q.Clear()
 }
 
-// Close is CloseTimeout with 30 seconds timeout.
-func (q *Queue) Close() error {
-	return q.CloseTimeout(30 * time.Second)
+// Start marks the queue as running. The underlying memqueue.Queue and
+// processor.Processor don't implement service.Service yet (that
+// conversion is a follow-up against those packages), so there is no
+// inner Start to call here — they begin processing as soon as they're
+// constructed, same as before.
+func (q *Queue) Start(ctx context.Context) error {
+	return q.BaseService.Starting()
 }
 
-// Close closes the queue waiting for pending messages to be processed.
-func (q *Queue) CloseTimeout(timeout time.Duration) error {
+// Stop stops the queue, waiting for pending messages to be processed
+// until ctx's deadline, or for up to 30 seconds if ctx has none.
+// Cancelling ctx does not end the wait early. Calling Stop before
+// Start, or more than once, is a no-op.
+func (q *Queue) Stop(ctx context.Context) error {
+	if !q.BaseService.Stopping() {
+		return nil
+	}
+
+	timeout := stopTimeout(ctx)
+
 	var firstErr error
 	if err := q.memqueue.CloseTimeout(timeout); err != nil && firstErr == nil {
 		firstErr = err
@@ -199,9 +219,33 @@ func (q *Queue) CloseTimeout(timeout time.Duration) error {
 			firstErr = err
 		}
 	}
+
+	q.BaseService.Stopped(firstErr)
 	return firstErr
 }
 
+// stopTimeout derives the timeout CloseTimeout/StopTimeout need from
+// ctx's deadline, falling back to 30 seconds if ctx has none.
+func stopTimeout(ctx context.Context) time.Duration {
+	if dl, ok := ctx.Deadline(); ok {
+		return time.Until(dl)
+	}
+	return 30 * time.Second
+}
+
+// Close is CloseTimeout with 30 seconds timeout.
+func (q *Queue) Close() error {
+	return q.CloseTimeout(30 * time.Second)
+}
+
+// CloseTimeout is a thin wrapper over Stop with a timeout, kept for
+// callers that don't manage a context.
+func (q *Queue) CloseTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.Stop(ctx)
+}
+
 func retry(fn func() error) error {
 	var err error
 	for i := 0; i < 3; i++ {
@@ -11,9 +11,10 @@ import (
 	. "github.com/onsi/gomega"
 	"gopkg.in/redis.v4"
 
-	"gopkg.in/queue.v1"
-	"gopkg.in/queue.v1/memqueue"
-	"gopkg.in/queue.v1/processor"
+	"github.com/go-msgqueue/msgqueue"
+	"github.com/go-msgqueue/msgqueue/cache"
+	"github.com/go-msgqueue/msgqueue/memqueue"
+	"github.com/go-msgqueue/msgqueue/processor"
 )
 
 func TestMemqueue(t *testing.T) {
@@ -72,7 +73,7 @@ var _ = Describe("message with invalid number of args", func() {
 
 var _ = Describe("handler that expects Message", func() {
 	ch := make(chan bool, 10)
-	handler := func(msg *queue.Message) error {
+	handler := func(msg *msgqueue.Message) error {
 		Expect(msg.Args).To(Equal([]interface{}{"string", 42}))
 		ch <- true
 		return nil
@@ -81,7 +82,7 @@ var _ = Describe("handler that expects Message", func() {
 	BeforeEach(func() {
 		q := memqueue.NewMemqueue(&memqueue.Options{
 			Processor: processor.Options{
-				Handler: queue.HandlerFunc(handler),
+				Handler: msgqueue.HandlerFunc(handler),
 			},
 		})
 		q.CallAsync("string", 42)
@@ -140,7 +141,7 @@ var _ = Describe("message retry timing", func() {
 		var now time.Time
 
 		BeforeEach(func() {
-			msg := queue.NewMessage()
+			msg := msgqueue.NewMessage()
 			msg.Delay = 5 * backoff
 			now = time.Now().Add(msg.Delay)
 
@@ -174,7 +175,7 @@ var _ = Describe("message retry timing", func() {
 				},
 			})
 
-			msg := queue.NewMessage()
+			msg := msgqueue.NewMessage()
 			msg.Delay = time.Hour
 			q.AddAsync(msg)
 			now = time.Now()
@@ -230,7 +231,11 @@ type memqueueCache struct {
 }
 
 func (c memqueueCache) Exists(key string) bool {
-	return !c.SetNX(key, "", 12*time.Hour).Val()
+	return !c.SetNX(key, 12*time.Hour)
+}
+
+func (c memqueueCache) SetNX(key string, ttl time.Duration) bool {
+	return c.Ring.SetNX(key, "", ttl).Val()
 }
 
 func redisRing() *redis.Ring {
@@ -265,7 +270,7 @@ var _ = Describe("named message", func() {
 			go func() {
 				defer GinkgoRecover()
 				defer wg.Done()
-				msg := queue.NewMessage()
+				msg := msgqueue.NewMessage()
 				msg.Name = "myname"
 				q.AddAsync(msg)
 			}()
@@ -419,8 +424,12 @@ func BenchmarkCallAsync(b *testing.B) {
 }
 
 func BenchmarkNamedMessage(b *testing.B) {
+	// Layered puts an in-process LRU in front of Redis: the first lookup
+	// of "myname" sets it in Redis, the second finds it there and hoists
+	// it into L1, and the rest hit L1 instead of round-tripping to Redis
+	// on every AddAsync.
 	q := memqueue.NewMemqueue(&memqueue.Options{
-		Cache: memqueueCache{redisRing()},
+		Cache: cache.NewLayered(1000, memqueueCache{redisRing()}),
 		Processor: processor.Options{
 			Handler:    func() {},
 			BufferSize: 1000000,
@@ -432,7 +441,7 @@ func BenchmarkNamedMessage(b *testing.B) {
 
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			msg := queue.NewMessage()
+			msg := msgqueue.NewMessage()
 			msg.Name = "myname"
 			q.AddAsync(msg)
 		}
@@ -0,0 +1,66 @@
+package msgqueue
+
+import (
+	"fmt"
+	"time"
+)
+
+// Message is a message that can be added to a queue and processed by a
+// Handler.
+type Message struct {
+	// Id is assigned by the backend when the message is added to the queue.
+	Id string
+
+	// Name is the unique name of the message. Messages with the same name
+	// are deduplicated using Options.Cache.
+	Name string
+
+	// Args are the arguments passed to the handler.
+	Args []interface{}
+
+	// Body is the marshaled representation of the message, used by
+	// backends that store messages as opaque payloads.
+	Body string
+
+	// Delay specifies the duration the queue must wait before the message
+	// becomes visible to consumers.
+	Delay time.Duration
+
+	// ReservationId is set by the backend when the message is reserved.
+	ReservationId string
+	// ReservedCount is the number of times the message has been reserved.
+	ReservedCount int
+	// ReservationDeadline is the time by which the current reservation
+	// must be acted on (Delete or Release) before a backend is allowed
+	// to treat it as timed out and make the message available again.
+	// It is the zero Time when the message isn't currently reserved.
+	ReservationDeadline time.Time
+
+	// DeliveryTag is the AMQP delivery tag of the reservation, used by
+	// the amqp backend to Ack/Nack the underlying delivery.
+	DeliveryTag uint64
+
+	err error
+}
+
+// NewMessage creates a new message with the given handler args.
+func NewMessage(args ...interface{}) *Message {
+	return &Message{
+		Args: args,
+	}
+}
+
+// SetDelayName assigns Name and Delay so the message is only added once
+// per period, as used by CallOnce.
+func (m *Message) SetDelayName(period time.Duration, args ...interface{}) {
+	m.Delay = period
+	m.Name = delayName(period, args)
+}
+
+func (m *Message) String() string {
+	return fmt.Sprintf("Message<Id=%s Name=%s>", m.Id, m.Name)
+}
+
+func delayName(period time.Duration, args []interface{}) string {
+	return fmt.Sprintf("%v-%d", args, time.Now().UnixNano()/int64(period))
+}
@@ -0,0 +1,59 @@
+package msgqueue
+
+import (
+	"time"
+
+	"gopkg.in/redis.v4"
+)
+
+// Options configures a Queue and its Processor.
+type Options struct {
+	// Name is the queue name.
+	Name string
+	// GroupName is the name of the consumer group sharing the queue.
+	GroupName string
+
+	// Handler handles messages reserved off the queue.
+	Handler Handler
+	// FallbackHandler is called when Handler returns an error after
+	// RetryLimit attempts.
+	FallbackHandler interface{}
+
+	// RetryLimit is the maximum number of times a message is retried
+	// before being handed to FallbackHandler.
+	RetryLimit int
+	// MinBackoff is the minimum time to wait before retrying a message.
+	MinBackoff time.Duration
+
+	// ReservationTimeout is how long a reserved message stays invisible
+	// to other consumers before it is considered abandoned.
+	ReservationTimeout time.Duration
+
+	// Cache is used to deduplicate named messages. Defaults to an
+	// in-process no-op cache.
+	Cache Cache
+
+	// Codec marshals and unmarshals messages for backends that store the
+	// message as an opaque body. Defaults to DefaultCodec.
+	Codec Codec
+
+	// Redis is used by backends that need a shared broker connection,
+	// e.g. to synchronize named message dedup across processes.
+	Redis *redis.Ring
+}
+
+// Init fills in zero-valued options with defaults.
+func (opt *Options) Init() {
+	if opt.RetryLimit == 0 {
+		opt.RetryLimit = 64
+	}
+	if opt.MinBackoff == 0 {
+		opt.MinBackoff = time.Second
+	}
+	if opt.ReservationTimeout == 0 {
+		opt.ReservationTimeout = 60 * time.Second
+	}
+	if opt.Codec == nil {
+		opt.Codec = DefaultCodec
+	}
+}
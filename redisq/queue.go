@@ -0,0 +1,425 @@
+// Package redisq implements a processor.Queuer directly on top of Redis,
+// for teams that don't want to run a separate broker. Each task is
+// stored as a hash and queue state lives in a handful of lists/sorted
+// sets per queue; see the package doc on Queue for the key layout.
+package redisq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gopkg.in/redis.v4"
+
+	"github.com/go-msgqueue/msgqueue"
+	"github.com/go-msgqueue/msgqueue/processor"
+	"github.com/go-msgqueue/msgqueue/service"
+)
+
+const (
+	// leaseDuration must be longer than schedulerTick so the holder's
+	// renewal on the next tick lands before the lease expires, but short
+	// enough that a dead holder is replaced within a few ticks.
+	leaseDuration        = 3 * schedulerTick
+	schedulerTick        = time.Second
+	maxRetriesBeforeDead = 5
+)
+
+// renewLeaseScript extends leaseKey by leaseDuration only if it is still
+// held by token, so a process can never renew (or steal) a lease it
+// didn't acquire.
+var renewLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// reserveScript atomically moves up to ARGV[1] ids from the pending list
+// into the active ZSET, scored by the reservation deadline in ARGV[2]
+// (unix ms). It returns the ids that were reserved.
+var reserveScript = redis.NewScript(`
+local pending = KEYS[1]
+local active = KEYS[2]
+local n = tonumber(ARGV[1])
+local deadline = ARGV[2]
+local ids = {}
+for i = 1, n do
+	local id = redis.call('RPOP', pending)
+	if not id then
+		break
+	end
+	redis.call('ZADD', active, deadline, id)
+	table.insert(ids, id)
+end
+return ids
+`)
+
+// Queue implements processor.Queuer directly against Redis. Per task
+// it stores a HASH at "msgqueue:{<name>}:t:<id>" with fields msg
+// (codec-encoded body), timeout, retry_count and unique_key, and tracks
+// ids in:
+//
+//	pending   LIST  ids ready to be reserved
+//	scheduled ZSET  ids delayed, scored by unix_ms when they become due
+//	active    ZSET  ids reserved, scored by their reservation deadline
+//	dead      ZSET  ids whose retry_count passed maxRetriesBeforeDead
+//	                when recovered from active after a timed-out reservation
+type Queue struct {
+	redis *redis.Ring
+	opt   *msgqueue.Options
+
+	p *processor.Processor
+
+	stop chan struct{}
+
+	// leaseToken identifies this Queue instance as the holder of the
+	// scheduler lease, so acquireLease can tell its own lease apart from
+	// one held by another process sharing the same queue.
+	leaseToken string
+
+	service.BaseService
+}
+
+var _ processor.Queuer = (*Queue)(nil)
+var _ service.Service = (*Queue)(nil)
+
+func NewQueue(redisdb *redis.Ring, opt *msgqueue.Options) *Queue {
+	opt.Init()
+
+	return &Queue{
+		redis:      redisdb,
+		opt:        opt,
+		leaseToken: newTaskId(),
+	}
+}
+
+func (q *Queue) Name() string {
+	return q.opt.Name
+}
+
+func (q *Queue) String() string {
+	return fmt.Sprintf("Queue<Name=%s>", q.Name())
+}
+
+func (q *Queue) Options() *msgqueue.Options {
+	return q.opt
+}
+
+func (q *Queue) Processor() *processor.Processor {
+	if q.p == nil {
+		q.p = processor.New(q, q.opt)
+	}
+	return q.p
+}
+
+func (q *Queue) keyPrefix() string {
+	return fmt.Sprintf("msgqueue:{%s}", q.Name())
+}
+
+func (q *Queue) taskKey(id string) string {
+	return fmt.Sprintf("%s:t:%s", q.keyPrefix(), id)
+}
+
+func (q *Queue) pendingKey() string   { return q.keyPrefix() + ":pending" }
+func (q *Queue) scheduledKey() string { return q.keyPrefix() + ":scheduled" }
+func (q *Queue) activeKey() string    { return q.keyPrefix() + ":active" }
+func (q *Queue) deadKey() string      { return q.keyPrefix() + ":dead" }
+func (q *Queue) leaseKey() string     { return q.keyPrefix() + ":lease" }
+func (q *Queue) uniqueKey(name string) string {
+	return fmt.Sprintf("%s:u:%s", q.keyPrefix(), name)
+}
+
+func nowMs() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func newTaskId() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Add adds message to the queue.
+func (q *Queue) Add(msg *msgqueue.Message) error {
+	if msg.Id == "" {
+		msg.Id = newTaskId()
+	}
+
+	body, err := q.opt.Codec.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	pipe := q.redis.Pipeline()
+	pipe.HMSet(q.taskKey(msg.Id), map[string]string{
+		"msg":         string(body),
+		"timeout":     fmt.Sprintf("%d", int64(q.opt.ReservationTimeout/time.Millisecond)),
+		"retry_count": "0",
+		"unique_key":  msg.Name,
+	})
+	if msg.Delay > 0 {
+		pipe.ZAdd(q.scheduledKey(), redis.Z{
+			Score:  float64(nowMs() + int64(msg.Delay/time.Millisecond)),
+			Member: msg.Id,
+		})
+	} else {
+		pipe.LPush(q.pendingKey(), msg.Id)
+	}
+	_, err = pipe.Exec()
+	return err
+}
+
+// Call creates a message using the args and adds it to the queue.
+func (q *Queue) Call(args ...interface{}) error {
+	msg := msgqueue.NewMessage(args...)
+	return q.Add(msg)
+}
+
+// CallOnce works like Call, but it adds message with same args only
+// once in a period, using SETNX on the message name with the period as
+// TTL so the dedup window matches Cache-backed CallOnce exactly.
+func (q *Queue) CallOnce(period time.Duration, args ...interface{}) error {
+	msg := msgqueue.NewMessage(args...)
+	msg.SetDelayName(period, args...)
+
+	ok, err := q.redis.SetNX(q.uniqueKey(msg.Name), "1", period).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	return q.Add(msg)
+}
+
+func (q *Queue) ReserveN(n int) ([]msgqueue.Message, error) {
+	if n > 100 {
+		n = 100
+	}
+
+	deadline := nowMs() + int64(q.opt.ReservationTimeout/time.Millisecond)
+	res, err := reserveScript.Run(q.redis, []string{q.pendingKey(), q.activeKey()}, n, deadline).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ids, _ := res.([]interface{})
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	msgs := make([]msgqueue.Message, 0, len(ids))
+	for _, v := range ids {
+		id, _ := v.(string)
+
+		fields, err := q.redis.HMGet(q.taskKey(id), "msg", "retry_count").Result()
+		if err != nil {
+			return msgs, err
+		}
+		body, _ := fields[0].(string)
+
+		var msg msgqueue.Message
+		if err := q.opt.Codec.Unmarshal([]byte(body), &msg); err != nil {
+			return msgs, err
+		}
+		msg.Id = id
+		msg.Body = body
+		msg.ReservationId = id
+		if retryCount, ok := fields[1].(string); ok {
+			fmt.Sscanf(retryCount, "%d", &msg.ReservedCount)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// Release re-schedules msg to run again after delay.
+func (q *Queue) Release(msg *msgqueue.Message, delay time.Duration) error {
+	pipe := q.redis.Pipeline()
+	pipe.ZRem(q.activeKey(), msg.ReservationId)
+	pipe.HIncrBy(q.taskKey(msg.Id), "retry_count", 1)
+	pipe.ZAdd(q.scheduledKey(), redis.Z{
+		Score:  float64(nowMs() + int64(delay/time.Millisecond)),
+		Member: msg.Id,
+	})
+	_, err := pipe.Exec()
+	return err
+}
+
+// Delete removes msg from the queue entirely.
+func (q *Queue) Delete(msg *msgqueue.Message) error {
+	pipe := q.redis.Pipeline()
+	pipe.ZRem(q.activeKey(), msg.ReservationId)
+	pipe.Del(q.taskKey(msg.Id))
+	_, err := pipe.Exec()
+	return err
+}
+
+func (q *Queue) DeleteBatch(msgs []*msgqueue.Message) error {
+	for _, msg := range msgs {
+		if err := q.Delete(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Purge removes all tasks and queue state.
+func (q *Queue) Purge() error {
+	var ids []string
+	for _, key := range []string{q.pendingKey(), q.scheduledKey(), q.activeKey(), q.deadKey()} {
+		switch {
+		case key == q.pendingKey():
+			vs, err := q.redis.LRange(key, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+			ids = append(ids, vs...)
+		default:
+			vs, err := q.redis.ZRange(key, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+			ids = append(ids, vs...)
+		}
+	}
+
+	pipe := q.redis.Pipeline()
+	for _, id := range ids {
+		pipe.Del(q.taskKey(id))
+	}
+	pipe.Del(q.pendingKey(), q.scheduledKey(), q.activeKey(), q.deadKey())
+	_, err := pipe.Exec()
+	return err
+}
+
+// Start launches the background scheduler that promotes due scheduled
+// tasks and recovers timed-out reservations. It must be called before
+// the queue processes anything.
+func (q *Queue) Start(ctx context.Context) error {
+	if err := q.BaseService.Starting(); err != nil {
+		return err
+	}
+	q.stop = make(chan struct{})
+	go q.scheduler()
+	return nil
+}
+
+// Stop stops the background scheduler and processor, waiting for
+// in-flight messages to finish until ctx's deadline, or for up to 30
+// seconds if ctx has none. Cancelling ctx does not end the wait early.
+// Calling Stop before Start, or more than once, is a no-op.
+func (q *Queue) Stop(ctx context.Context) error {
+	if !q.BaseService.Stopping() {
+		return nil
+	}
+	close(q.stop)
+
+	var err error
+	if q.p != nil {
+		timeout := 30 * time.Second
+		if dl, ok := ctx.Deadline(); ok {
+			timeout = time.Until(dl)
+		}
+		err = q.p.StopTimeout(timeout)
+	}
+
+	q.BaseService.Stopped(err)
+	return err
+}
+
+// Close is CloseTimeout with 30 seconds timeout.
+func (q *Queue) Close() error {
+	return q.CloseTimeout(30 * time.Second)
+}
+
+// CloseTimeout is a thin wrapper over Stop with a timeout, kept for
+// callers that don't manage a context.
+func (q *Queue) CloseTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.Stop(ctx)
+}
+
+// scheduler promotes due scheduled tasks into pending and recovers
+// active tasks whose reservation deadline passed without an Ack/Nack.
+// Only one Queue instance across the fleet runs it at a time, elected
+// via a SETNX lease so multiple processes can share the same queue.
+func (q *Queue) scheduler() {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+			if !q.acquireLease() {
+				continue
+			}
+			q.promoteScheduled()
+			q.recoverActive()
+		}
+	}
+}
+
+// acquireLease reports whether this Queue instance holds the scheduler
+// lease for the current tick, renewing it if it already does and
+// otherwise trying to claim it from scratch. Because leaseDuration
+// outlives a single tick, the current holder renews before it expires
+// and promoteScheduled/recoverActive keep running every tick rather
+// than only once per lease period.
+func (q *Queue) acquireLease() bool {
+	renewed, err := renewLeaseScript.Run(q.redis, []string{q.leaseKey()}, q.leaseToken, leaseDuration.Milliseconds()).Result()
+	if err == nil {
+		if n, _ := renewed.(int64); n == 1 {
+			return true
+		}
+	}
+
+	ok, err := q.redis.SetNX(q.leaseKey(), q.leaseToken, leaseDuration).Result()
+	return err == nil && ok
+}
+
+func (q *Queue) promoteScheduled() {
+	ids, err := q.redis.ZRangeByScore(q.scheduledKey(), redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", nowMs()),
+	}).Result()
+	if err != nil || len(ids) == 0 {
+		return
+	}
+
+	pipe := q.redis.Pipeline()
+	for _, id := range ids {
+		pipe.ZRem(q.scheduledKey(), id)
+		pipe.LPush(q.pendingKey(), id)
+	}
+	_, _ = pipe.Exec()
+}
+
+func (q *Queue) recoverActive() {
+	ids, err := q.redis.ZRangeByScore(q.activeKey(), redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", nowMs()),
+	}).Result()
+	if err != nil || len(ids) == 0 {
+		return
+	}
+
+	for _, id := range ids {
+		retryCount, _ := q.redis.HIncrBy(q.taskKey(id), "retry_count", 1).Result()
+
+		pipe := q.redis.Pipeline()
+		pipe.ZRem(q.activeKey(), id)
+		if int(retryCount) > maxRetriesBeforeDead {
+			pipe.ZAdd(q.deadKey(), redis.Z{Score: float64(nowMs()), Member: id})
+		} else {
+			pipe.LPush(q.pendingKey(), id)
+		}
+		_, _ = pipe.Exec()
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrAlreadyStarted is returned by BaseService.Starting when Start has
+// already been called once.
+var ErrAlreadyStarted = errors.New("service: already started")
+
+type state int
+
+const (
+	stateIdle state = iota
+	stateRunning
+	stateStopped
+)
+
+// BaseService is embedded by types that implement Service to get
+// single-Start/single-Stop transitions for free. The embedder's own
+// Start and Stop methods call Starting, Stopping and Stopped around
+// their actual work; BaseService provides IsRunning, Wait and
+// OnStopped directly.
+type BaseService struct {
+	mu    sync.Mutex
+	state state
+
+	done    chan struct{}
+	stopErr error
+	stopped bool
+
+	onStopped []func(error)
+}
+
+// Starting transitions the service to running. The embedder's Start
+// method should call it first and return its error without doing any
+// work if it is non-nil.
+func (s *BaseService) Starting() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != stateIdle {
+		return ErrAlreadyStarted
+	}
+	s.state = stateRunning
+	s.done = make(chan struct{})
+	return nil
+}
+
+// StartFailed reverts a service that called Starting back to idle. The
+// embedder's Start method should call it if the work it does after
+// Starting fails, so a retried Start is not rejected with
+// ErrAlreadyStarted forever and IsRunning does not keep reporting true
+// for a service that never actually came up.
+func (s *BaseService) StartFailed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != stateRunning {
+		return
+	}
+	s.state = stateIdle
+	s.done = nil
+}
+
+// Stopping transitions the service to stopped and reports whether the
+// caller should actually perform shutdown work. The embedder's Stop
+// method should call it first and return nil immediately when ok is
+// false, since that means the service was never started or Stop has
+// already run.
+func (s *BaseService) Stopping() (ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != stateRunning {
+		return false
+	}
+	s.state = stateStopped
+	return true
+}
+
+// Stopped records the result of shutting down, runs any OnStopped
+// callbacks and unblocks Wait. The embedder's Stop method calls it
+// once, after Stopping returned true and shutdown work is done.
+func (s *BaseService) Stopped(err error) {
+	s.mu.Lock()
+	s.stopErr = err
+	s.stopped = true
+	callbacks := s.onStopped
+	s.onStopped = nil
+	done := s.done
+	s.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(err)
+	}
+	close(done)
+}
+
+func (s *BaseService) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state == stateRunning
+}
+
+// Wait blocks until Stopped has been called and returns its error. If
+// Starting has not been called, it returns nil immediately.
+func (s *BaseService) Wait() error {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopErr
+}
+
+func (s *BaseService) OnStopped(fn func(error)) {
+	s.mu.Lock()
+	if !s.stopped {
+		s.onStopped = append(s.onStopped, fn)
+		s.mu.Unlock()
+		return
+	}
+	err := s.stopErr
+	s.mu.Unlock()
+
+	fn(err)
+}
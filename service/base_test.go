@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-msgqueue/msgqueue/service"
+)
+
+func TestBaseServiceStartStop(t *testing.T) {
+	var s service.BaseService
+
+	if s.IsRunning() {
+		t.Fatal("new service should not be running")
+	}
+
+	if err := s.Starting(); err != nil {
+		t.Fatalf("Starting: %v", err)
+	}
+	if !s.IsRunning() {
+		t.Fatal("IsRunning should be true after Starting")
+	}
+
+	if err := s.Starting(); err != service.ErrAlreadyStarted {
+		t.Fatalf("second Starting should return ErrAlreadyStarted, got %v", err)
+	}
+
+	if !s.Stopping() {
+		t.Fatal("Stopping should report ok the first time")
+	}
+	s.Stopped(nil)
+
+	if s.IsRunning() {
+		t.Fatal("IsRunning should be false after Stopped")
+	}
+	if s.Stopping() {
+		t.Fatal("Stopping should be a no-op the second time")
+	}
+	if err := s.Wait(); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+}
+
+func TestBaseServiceStartFailed(t *testing.T) {
+	var s service.BaseService
+
+	if err := s.Starting(); err != nil {
+		t.Fatalf("Starting: %v", err)
+	}
+	s.StartFailed()
+
+	if s.IsRunning() {
+		t.Fatal("IsRunning should be false after StartFailed")
+	}
+
+	if err := s.Starting(); err != nil {
+		t.Fatalf("Starting after StartFailed should be allowed, got %v", err)
+	}
+	if !s.IsRunning() {
+		t.Fatal("IsRunning should be true after re-Starting")
+	}
+}
+
+func TestBaseServiceWaitError(t *testing.T) {
+	var s service.BaseService
+	wantErr := errors.New("boom")
+
+	if err := s.Starting(); err != nil {
+		t.Fatalf("Starting: %v", err)
+	}
+	if !s.Stopping() {
+		t.Fatal("Stopping should report ok")
+	}
+	s.Stopped(wantErr)
+
+	if err := s.Wait(); err != wantErr {
+		t.Fatalf("Wait = %v, want %v", err, wantErr)
+	}
+}
+
+func TestBaseServiceOnStoppedAfterStop(t *testing.T) {
+	var s service.BaseService
+	wantErr := errors.New("boom")
+
+	if err := s.Starting(); err != nil {
+		t.Fatalf("Starting: %v", err)
+	}
+	s.Stopping()
+	s.Stopped(wantErr)
+
+	var got error
+	called := false
+	s.OnStopped(func(err error) {
+		called = true
+		got = err
+	})
+
+	if !called {
+		t.Fatal("OnStopped callback registered after Stopped should run immediately")
+	}
+	if got != wantErr {
+		t.Fatalf("OnStopped err = %v, want %v", got, wantErr)
+	}
+}
@@ -0,0 +1,23 @@
+// Package service gives queues and processors a uniform start/stop
+// lifecycle, so shutdown is a matter of calling Stop and waiting for it
+// instead of each type inventing its own (often racy) Close method.
+package service
+
+import "context"
+
+// Service is anything with an explicit start/stop lifecycle.
+type Service interface {
+	// Start begins the service's work. It must not be called twice.
+	Start(ctx context.Context) error
+	// Stop asks the service to shut down, blocking until it has or ctx
+	// is done. Calling Stop before Start, or more than once, is a no-op.
+	Stop(ctx context.Context) error
+	// Wait blocks until the service has stopped and returns the error,
+	// if any, that Stop completed with.
+	Wait() error
+	// IsRunning reports whether Start has been called and Stop has not.
+	IsRunning() bool
+	// OnStopped registers fn to run once the service stops. fn runs
+	// immediately, synchronously, if the service has already stopped.
+	OnStopped(fn func(error))
+}
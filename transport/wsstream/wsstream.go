@@ -0,0 +1,162 @@
+// Package wsstream streams reserved messages to a WebSocket client
+// instead of running a Handler in-process, so browser dashboards and
+// non-Go workers can pull tasks off a queue without polling.
+package wsstream
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/go-msgqueue/msgqueue"
+	"github.com/go-msgqueue/msgqueue/processor"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+const reservePollInterval = time.Second
+
+type outFrame struct {
+	Id            string `json:"id"`
+	ReservationId string `json:"reservation_id"`
+	Body          string `json:"body"`
+}
+
+type inFrame struct {
+	Ack     string `json:"ack"`
+	Nack    string `json:"nack"`
+	DelayMs int64  `json:"delay_ms"`
+}
+
+// Serve upgrades r to a WebSocket and streams messages reserved off q as
+// JSON frames until the client disconnects. Frames it receives back,
+// {"ack":"<id>"} or {"nack":"<id>","delay_ms":n}, are mapped to
+// q.Delete and q.Release respectively. Any message still outstanding
+// when the connection drops is released back to the queue so it isn't
+// stuck until ReservationTimeout.
+func Serve(q processor.Queuer, w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("wsstream: upgrade failed: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	c := &wsConn{
+		q:           q,
+		conn:        conn,
+		outstanding: make(map[string]*msgqueue.Message),
+	}
+	c.run()
+}
+
+type wsConn struct {
+	q    processor.Queuer
+	conn *websocket.Conn
+
+	mu          sync.Mutex
+	outstanding map[string]*msgqueue.Message
+}
+
+func (c *wsConn) run() {
+	done := make(chan struct{})
+	go c.readLoop(done)
+
+	ticker := time.NewTicker(reservePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			c.releaseOutstanding()
+			return
+		case <-ticker.C:
+			if !c.reserveAndSend() {
+				c.releaseOutstanding()
+				return
+			}
+		}
+	}
+}
+
+func (c *wsConn) reserveAndSend() bool {
+	msgs, err := c.q.ReserveN(10)
+	if err != nil {
+		log.Printf("wsstream: ReserveN failed: %s", err)
+		return true
+	}
+
+	for i := range msgs {
+		msg := msgs[i]
+
+		c.mu.Lock()
+		c.outstanding[msg.Id] = &msg
+		c.mu.Unlock()
+
+		_ = c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		err := c.conn.WriteJSON(outFrame{
+			Id:            msg.Id,
+			ReservationId: msg.ReservationId,
+			Body:          msg.Body,
+		})
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *wsConn) readLoop(done chan struct{}) {
+	defer close(done)
+
+	for {
+		var frame inFrame
+		if err := c.conn.ReadJSON(&frame); err != nil {
+			return
+		}
+
+		if frame.Ack != "" {
+			if msg := c.takeOutstanding(frame.Ack); msg != nil {
+				if err := c.q.Delete(msg); err != nil {
+					log.Printf("wsstream: Delete failed: %s", err)
+				}
+			}
+			continue
+		}
+
+		if frame.Nack != "" {
+			if msg := c.takeOutstanding(frame.Nack); msg != nil {
+				delay := time.Duration(frame.DelayMs) * time.Millisecond
+				if err := c.q.Release(msg, delay); err != nil {
+					log.Printf("wsstream: Release failed: %s", err)
+				}
+			}
+		}
+	}
+}
+
+func (c *wsConn) takeOutstanding(id string) *msgqueue.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msg := c.outstanding[id]
+	delete(c.outstanding, id)
+	return msg
+}
+
+func (c *wsConn) releaseOutstanding() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, msg := range c.outstanding {
+		if err := c.q.Release(msg, 0); err != nil {
+			log.Printf("wsstream: Release on disconnect failed: %s", err)
+		}
+		delete(c.outstanding, id)
+	}
+}